@@ -0,0 +1,230 @@
+package jobs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// jobsBucket holds one small jobRecord per job: status, progress,
+	// the original address list, and the next result sequence number.
+	// It never grows with the number of results, so appending a result
+	// doesn't re-marshal an ever-larger blob.
+	jobsBucket = []byte("jobs")
+	// resultsBucket holds individual Results, keyed by resultKey so
+	// they sort in seq order within a job and can be range-scanned
+	// without touching any other job's results.
+	resultsBucket = []byte("results")
+)
+
+// jobRecord is the small, fixed-shape record stored per job in
+// jobsBucket; its Results live in resultsBucket instead.
+type jobRecord struct {
+	ID        string    `json:"id"`
+	Status    Status    `json:"status"`
+	Progress  Progress  `json:"progress"`
+	Emails    []string  `json:"emails"`
+	NextSeq   uint64    `json:"next_seq"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BoltStore is a Store backed by an embedded BoltDB file, so a job's
+// progress and results survive a process restart.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore opens (creating if necessary) a BoltDB file at path.
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(jobsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(resultsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+func (b *BoltStore) Create(id string, emails []string, createdAt time.Time) error {
+	rec := jobRecord{
+		ID:        id,
+		Status:    StatusRunning,
+		Progress:  Progress{Total: len(emails)},
+		Emails:    emails,
+		CreatedAt: createdAt,
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return putJobRecord(tx.Bucket(jobsBucket), rec)
+	})
+}
+
+func (b *BoltStore) Get(id string) (Job, bool, error) {
+	var rec jobRecord
+	var results []Result
+	found := false
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(jobsBucket).Get([]byte(id))
+		if v == nil {
+			return nil
+		}
+		found = true
+		if err := json.Unmarshal(v, &rec); err != nil {
+			return err
+		}
+
+		var err error
+		results, err = scanResults(tx.Bucket(resultsBucket), id, 0)
+		return err
+	})
+	if err != nil || !found {
+		return Job{}, found, err
+	}
+
+	return jobFromRecord(rec, results), true, nil
+}
+
+func (b *BoltStore) GetStatus(id string) (Status, Progress, bool, error) {
+	var rec jobRecord
+	found := false
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(jobsBucket).Get([]byte(id))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(v, &rec)
+	})
+	return rec.Status, rec.Progress, found, err
+}
+
+func (b *BoltStore) AppendResult(id, email string, response json.RawMessage, verdict string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		jobsB := tx.Bucket(jobsBucket)
+		v := jobsB.Get([]byte(id))
+		if v == nil {
+			return fmt.Errorf("job %s not found", id)
+		}
+
+		var rec jobRecord
+		if err := json.Unmarshal(v, &rec); err != nil {
+			return err
+		}
+
+		rec.NextSeq++
+		seq := rec.NextSeq
+		applyVerdict(&rec.Progress, verdict)
+		if rec.Progress.Done >= rec.Progress.Total {
+			rec.Status = StatusDone
+		}
+
+		data, err := json.Marshal(Result{Seq: seq, Email: email, Response: response})
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket(resultsBucket).Put(resultKey(id, seq), data); err != nil {
+			return err
+		}
+
+		return putJobRecord(jobsB, rec)
+	})
+}
+
+func (b *BoltStore) ResultsSince(id string, afterSeq uint64) ([]Result, error) {
+	var out []Result
+	err := b.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(jobsBucket).Get([]byte(id)) == nil {
+			return fmt.Errorf("job %s not found", id)
+		}
+
+		var err error
+		out, err = scanResults(tx.Bucket(resultsBucket), id, afterSeq)
+		return err
+	})
+	return out, err
+}
+
+func (b *BoltStore) ListRunning() ([]Job, error) {
+	var out []Job
+	err := b.db.View(func(tx *bolt.Tx) error {
+		resultsB := tx.Bucket(resultsBucket)
+		return tx.Bucket(jobsBucket).ForEach(func(k, v []byte) error {
+			var rec jobRecord
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return err
+			}
+			if rec.Status != StatusRunning {
+				return nil
+			}
+
+			results, err := scanResults(resultsB, rec.ID, 0)
+			if err != nil {
+				return err
+			}
+			out = append(out, jobFromRecord(rec, results))
+			return nil
+		})
+	})
+	return out, err
+}
+
+func (b *BoltStore) Close() error {
+	return b.db.Close()
+}
+
+func putJobRecord(bucket *bolt.Bucket, rec jobRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(rec.ID), data)
+}
+
+func jobFromRecord(rec jobRecord, results []Result) Job {
+	return Job{
+		ID:        rec.ID,
+		Status:    rec.Status,
+		Progress:  rec.Progress,
+		Emails:    rec.Emails,
+		Results:   results,
+		CreatedAt: rec.CreatedAt,
+	}
+}
+
+// resultKey orders a job's results lexicographically by seq (zero-padded
+// to sort correctly) within a "<id>|" prefix, so they can be
+// range-scanned independently of every other job sharing the bucket.
+func resultKey(id string, seq uint64) []byte {
+	return []byte(fmt.Sprintf("%s|%020d", id, seq))
+}
+
+func scanResults(bucket *bolt.Bucket, id string, afterSeq uint64) ([]Result, error) {
+	prefix := []byte(id + "|")
+
+	var out []Result
+	c := bucket.Cursor()
+	for k, v := c.Seek(resultKey(id, afterSeq+1)); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		var r Result
+		if err := json.Unmarshal(v, &r); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
@@ -0,0 +1,208 @@
+// Package jobs implements an async, resumable job API for bulk email
+// verification: a batch is queued once, verified in the background,
+// and its progress and results can be polled or streamed across client
+// reconnects and server restarts.
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status is a Job's lifecycle state.
+type Status string
+
+const (
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+)
+
+// Progress summarizes a Job's completion so far.
+type Progress struct {
+	Total   int `json:"total"`
+	Done    int `json:"done"`
+	Valid   int `json:"valid"`
+	Invalid int `json:"invalid"`
+	Unknown int `json:"unknown"`
+}
+
+// Result is one address's verification outcome, numbered by Seq so an
+// SSE client can resume from Last-Event-ID after a disconnect.
+type Result struct {
+	Seq      uint64          `json:"seq"`
+	Email    string          `json:"email"`
+	Response json.RawMessage `json:"response"`
+}
+
+// Job is a queued bulk verification batch. Emails is the original
+// input list, kept so an interrupted job can be resumed against only
+// the addresses it hasn't recorded a Result for yet.
+type Job struct {
+	ID        string    `json:"id"`
+	Status    Status    `json:"status"`
+	Progress  Progress  `json:"progress"`
+	Emails    []string  `json:"emails"`
+	Results   []Result  `json:"results"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Store persists Jobs and their results so a batch survives a client
+// disconnect or a server redeploy.
+type Store interface {
+	Create(id string, emails []string, createdAt time.Time) error
+	Get(id string) (Job, bool, error)
+	// GetStatus returns a job's status and progress without fetching its
+	// (potentially large) result list, for callers that only need to
+	// know whether it's still running.
+	GetStatus(id string) (Status, Progress, bool, error)
+	// AppendResult records one address's outcome, advances Progress,
+	// and marks the job done once every address has a result. verdict
+	// ("valid", "invalid", or anything else treated as "unknown")
+	// selects which Progress counter is incremented.
+	AppendResult(id string, email string, response json.RawMessage, verdict string) error
+	// ResultsSince returns results with Seq > afterSeq, in order.
+	ResultsSince(id string, afterSeq uint64) ([]Result, error)
+	// ListRunning returns every job still in StatusRunning, so an
+	// interrupted batch can be re-driven to completion after a restart.
+	ListRunning() ([]Job, error)
+	Close() error
+}
+
+type memoryJob struct {
+	mu  sync.Mutex
+	job Job
+}
+
+// MemoryStore is an in-process Store backed by a map. It does not
+// survive process restarts.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	jobs map[string]*memoryJob
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]*memoryJob)}
+}
+
+func (m *MemoryStore) Create(id string, emails []string, createdAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[id] = &memoryJob{job: Job{
+		ID:        id,
+		Status:    StatusRunning,
+		Progress:  Progress{Total: len(emails)},
+		Emails:    append([]string(nil), emails...),
+		CreatedAt: createdAt,
+	}}
+	return nil
+}
+
+func (m *MemoryStore) Get(id string) (Job, bool, error) {
+	m.mu.RLock()
+	mj, ok := m.jobs[id]
+	m.mu.RUnlock()
+	if !ok {
+		return Job{}, false, nil
+	}
+
+	mj.mu.Lock()
+	defer mj.mu.Unlock()
+	return cloneJob(mj.job), true, nil
+}
+
+func (m *MemoryStore) GetStatus(id string) (Status, Progress, bool, error) {
+	m.mu.RLock()
+	mj, ok := m.jobs[id]
+	m.mu.RUnlock()
+	if !ok {
+		return "", Progress{}, false, nil
+	}
+
+	mj.mu.Lock()
+	defer mj.mu.Unlock()
+	return mj.job.Status, mj.job.Progress, true, nil
+}
+
+func (m *MemoryStore) AppendResult(id, email string, response json.RawMessage, verdict string) error {
+	m.mu.RLock()
+	mj, ok := m.jobs[id]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("job %s not found", id)
+	}
+
+	mj.mu.Lock()
+	defer mj.mu.Unlock()
+
+	seq := uint64(len(mj.job.Results)) + 1
+	mj.job.Results = append(mj.job.Results, Result{Seq: seq, Email: email, Response: response})
+	applyVerdict(&mj.job.Progress, verdict)
+	if mj.job.Progress.Done >= mj.job.Progress.Total {
+		mj.job.Status = StatusDone
+	}
+	return nil
+}
+
+func (m *MemoryStore) ResultsSince(id string, afterSeq uint64) ([]Result, error) {
+	m.mu.RLock()
+	mj, ok := m.jobs[id]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+
+	mj.mu.Lock()
+	defer mj.mu.Unlock()
+
+	var out []Result
+	for _, r := range mj.job.Results {
+		if r.Seq > afterSeq {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) ListRunning() ([]Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var out []Job
+	for _, mj := range m.jobs {
+		mj.mu.Lock()
+		if mj.job.Status == StatusRunning {
+			out = append(out, cloneJob(mj.job))
+		}
+		mj.mu.Unlock()
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) Close() error { return nil }
+
+func cloneJob(j Job) Job {
+	results := make([]Result, len(j.Results))
+	copy(results, j.Results)
+	j.Results = results
+
+	emails := make([]string, len(j.Emails))
+	copy(emails, j.Emails)
+	j.Emails = emails
+
+	return j
+}
+
+func applyVerdict(p *Progress, verdict string) {
+	p.Done++
+	switch verdict {
+	case "valid":
+		p.Valid++
+	case "invalid":
+		p.Invalid++
+	default:
+		p.Unknown++
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/is-it-fake/core/jobs"
+	"github.com/is-it-fake/core/smtppool"
+)
+
+// jobStore backs the async job API. Set in main().
+var jobStore jobs.Store
+
+// newJobStore builds the configured jobs.Store backend.
+func newJobStore(kind, boltPath string) (jobs.Store, error) {
+	switch kind {
+	case "memory":
+		return jobs.NewMemoryStore(), nil
+	case "bolt":
+		return jobs.NewBoltStore(boltPath)
+	default:
+		return nil, fmt.Errorf("unknown job store backend %q", kind)
+	}
+}
+
+func newJobID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand failures don't happen on supported platforms; fall
+		// back to something still unique rather than failing the request.
+		return fmt.Sprintf("job-%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// createJobHandler queues a bulk verification batch and returns
+// immediately with a job id the caller can poll or stream.
+func createJobHandler(c *gin.Context) {
+	var req BulkEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	id := newJobID()
+	if err := jobStore.Create(id, req.Emails, time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	go runBulkJob(id, req.Emails)
+
+	c.JSON(http.StatusAccepted, gin.H{"job_id": id})
+}
+
+// runBulkJob verifies every address in the batch, consulting and
+// populating resultStore the same way the synchronous endpoints do, and
+// records each outcome in jobStore as it completes. Concurrency is
+// bounded globally by runWithGlobalLimit on top of the pool's per-host
+// cap, the same way the synchronous bulk endpoint is.
+func runBulkJob(id string, emails []string) {
+	pool := smtppool.New(smtppool.WithRatePerSecond(bulkRatePerSecondPerHost))
+	defer pool.Close()
+
+	runWithGlobalLimit(emails, func(mail string) {
+		key := normalizeEmail(mail)
+		response, ok := lookupCachedResponse(key)
+		if !ok {
+			response, ok = classify(mail)
+			if ok {
+				valid, catchAll, err := verifyEmailViaPool(pool, mail)
+				response = finishVerification(response, valid, catchAll, err)
+			}
+			cacheResponse(key, response)
+		}
+
+		data, err := json.Marshal(response)
+		if err != nil {
+			log.Printf("job %s: failed to encode result for %s: %v", id, mail, err)
+			return
+		}
+		if err := jobStore.AppendResult(id, mail, data, response.Verdict); err != nil {
+			log.Printf("job %s: failed to append result for %s: %v", id, mail, err)
+		}
+	})
+}
+
+// resumeJobs re-drives every job still StatusRunning when the store
+// was opened, so a batch interrupted by a restart (the bolt backend's
+// whole point) keeps going instead of hanging forever at its
+// partially-recorded progress. Already-recorded addresses are skipped.
+func resumeJobs() {
+	running, err := jobStore.ListRunning()
+	if err != nil {
+		log.Printf("failed to list running jobs to resume: %v", err)
+		return
+	}
+
+	for _, job := range running {
+		// Total counts every occurrence of a duplicated address, and
+		// AppendResult is called once per occurrence too (runBulkJob
+		// iterates the original, non-deduped list), so recorded must be
+		// matched off by count, not by presence, or a repeated address
+		// permanently pins the job at Done < Total after a resume.
+		recorded := make(map[string]int, len(job.Results))
+		for _, r := range job.Results {
+			recorded[normalizeEmail(r.Email)]++
+		}
+
+		remaining := make([]string, 0, len(job.Emails))
+		for _, email := range job.Emails {
+			key := normalizeEmail(email)
+			if recorded[key] > 0 {
+				recorded[key]--
+				continue
+			}
+			remaining = append(remaining, email)
+		}
+		if len(remaining) == 0 {
+			continue
+		}
+
+		log.Printf("job %s: resuming %d/%d unrecorded addresses", job.ID, len(remaining), len(job.Emails))
+		go runBulkJob(job.ID, remaining)
+	}
+}
+
+// getJobHandler returns a job's status and progress (not its full
+// result list; use the results.* or stream endpoints for that).
+func getJobHandler(c *gin.Context) {
+	id := c.Param("id")
+	status, progress, found, err := jobStore.GetStatus(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":       id,
+		"status":   status,
+		"progress": progress,
+	})
+}
+
+const jobStreamPollInterval = 300 * time.Millisecond
+
+// jobStreamHandler streams job results as they complete. A client that
+// reconnects sends the last event id it saw via the standard
+// Last-Event-ID header, and only results after that sequence number are
+// replayed.
+func jobStreamHandler(c *gin.Context) {
+	id := c.Param("id")
+	if _, _, found, err := jobStore.GetStatus(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	} else if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	}
+
+	var lastSeq uint64
+	if v := c.GetHeader("Last-Event-ID"); v != "" {
+		if parsed, err := strconv.ParseUint(v, 10, 64); err == nil {
+			lastSeq = parsed
+		}
+	}
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+
+	ticker := time.NewTicker(jobStreamPollInterval)
+	defer ticker.Stop()
+
+	for {
+		results, err := jobStore.ResultsSince(id, lastSeq)
+		if err != nil {
+			log.Printf("job %s: stream lookup failed: %v", id, err)
+			return
+		}
+		for _, r := range results {
+			fmt.Fprintf(c.Writer, "id: %d\nevent: emailResult\ndata: %s\n\n", r.Seq, r.Response)
+			lastSeq = r.Seq
+		}
+		if len(results) > 0 {
+			c.Writer.Flush()
+		}
+
+		status, _, found, err := jobStore.GetStatus(id)
+		if err == nil && found && status == jobs.StatusDone && len(results) == 0 {
+			fmt.Fprintf(c.Writer, "event: end\ndata: done\n\n")
+			c.Writer.Flush()
+			return
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// jobResultsHandler returns a job's full result set in the given
+// format, for clients that want the final list rather than a live
+// stream.
+func jobResultsHandler(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, found, err := jobStore.Get(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !found {
+			c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+			return
+		}
+
+		switch format {
+		case "json":
+			c.JSON(http.StatusOK, job.Results)
+
+		case "ndjson":
+			c.Writer.Header().Set("Content-Type", "application/x-ndjson")
+			for _, r := range job.Results {
+				c.Writer.Write(r.Response)
+				c.Writer.Write([]byte("\n"))
+			}
+
+		case "csv":
+			c.Writer.Header().Set("Content-Type", "text/csv")
+			w := csv.NewWriter(c.Writer)
+			w.Write([]string{"seq", "email", "response"})
+			for _, r := range job.Results {
+				w.Write([]string{strconv.FormatUint(r.Seq, 10), r.Email, string(r.Response)})
+			}
+			w.Flush()
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	_ "embed"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+//go:embed data/disposable_domains.txt
+var defaultDisposableList string
+
+//go:embed data/free_providers.txt
+var defaultFreeProviderList string
+
+// roleLocalParts are local-parts that identify a role/shared mailbox
+// (info@, admin@, support@, ...) rather than an individual.
+var roleLocalParts = map[string]struct{}{
+	"admin": {}, "administrator": {}, "support": {}, "info": {}, "sales": {},
+	"contact": {}, "help": {}, "billing": {}, "abuse": {}, "postmaster": {},
+	"webmaster": {}, "noreply": {}, "no-reply": {}, "root": {}, "hostmaster": {},
+	"marketing": {}, "hr": {}, "jobs": {}, "security": {}, "privacy": {},
+}
+
+var (
+	listsMu             sync.RWMutex
+	disposableDomains   map[string]struct{}
+	freeProviderDomains map[string]struct{}
+
+	// listsDir, when set, holds operator-provided overrides for the
+	// built-in disposable/free-provider lists.
+	listsDir string
+)
+
+// initLists loads the disposable/free-provider lists (from dir if set,
+// otherwise the lists baked into the binary) and starts a SIGHUP watcher
+// so operators can refresh them without restarting the process.
+func initLists(dir string) {
+	listsDir = dir
+	reloadLists()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	go func() {
+		for range sigCh {
+			log.Println("SIGHUP received, reloading disposable/free-provider lists")
+			reloadLists()
+		}
+	}()
+}
+
+func reloadLists() {
+	disposable := loadList(defaultDisposableList, "disposable_domains.txt")
+	free := loadList(defaultFreeProviderList, "free_providers.txt")
+
+	listsMu.Lock()
+	disposableDomains = disposable
+	freeProviderDomains = free
+	listsMu.Unlock()
+}
+
+// loadList reads filename from listsDir if present, otherwise falls back
+// to the embedded default contents.
+func loadList(embedded string, filename string) map[string]struct{} {
+	if listsDir != "" {
+		f, err := os.Open(filepath.Join(listsDir, filename))
+		if err == nil {
+			defer f.Close()
+			return parseList(f)
+		}
+		log.Printf("lists-dir override for %s unavailable, using built-in list: %v", filename, err)
+	}
+	return parseList(strings.NewReader(embedded))
+}
+
+func parseList(r io.Reader) map[string]struct{} {
+	set := make(map[string]struct{})
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		set[line] = struct{}{}
+	}
+	return set
+}
+
+func isDisposableDomain(domain string) bool {
+	listsMu.RLock()
+	defer listsMu.RUnlock()
+	_, ok := disposableDomains[domain]
+	return ok
+}
+
+func isFreeProvider(domain string) bool {
+	listsMu.RLock()
+	defer listsMu.RUnlock()
+	_, ok := freeProviderDomains[domain]
+	return ok
+}
+
+func isRoleLocalPart(local string) bool {
+	_, ok := roleLocalParts[local]
+	return ok
+}
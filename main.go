@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/smtp"
@@ -12,6 +16,10 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+
+	"github.com/is-it-fake/core/smtppool"
+	"github.com/is-it-fake/core/store"
+	"github.com/is-it-fake/core/verifier"
 )
 
 type EmailRequest struct {
@@ -23,19 +31,102 @@ type BulkEmailRequest struct {
 	Emails []string `json:"emails"`
 }
 
+// EmailResponse carries the verdict for a single address plus the
+// structured classification flags surfaced by the verification pipeline.
 type EmailResponse struct {
 	Email   string `json:"email"`
 	Valid   bool   `json:"valid"`
 	Message string `json:"message"`
+
+	SyntaxValid  bool `json:"syntax_valid"`
+	Disposable   bool `json:"disposable"`
+	RoleAccount  bool `json:"role_account"`
+	FreeProvider bool `json:"free_provider"`
+	CatchAll     bool `json:"catch_all"`
+
+	// Verdict is one of "valid", "invalid", or "unknown" and drives how
+	// long the result is cached in resultStore.
+	Verdict string `json:"verdict"`
+}
+
+// Result store TTLs, keyed by EmailResponse.Verdict.
+const (
+	resultTTLValid   = 7 * 24 * time.Hour
+	resultTTLInvalid = 30 * 24 * time.Hour
+	resultTTLUnknown = 1 * time.Hour
+)
+
+// resultStore caches full EmailResponse objects so repeat lookups of
+// the same address skip SMTP traffic entirely. Set in main(); nil
+// disables caching (e.g. if initialization fails in a way callers
+// choose to tolerate).
+var resultStore store.ResultStore
+
+// newResultStore builds the configured ResultStore backend.
+func newResultStore(kind, boltPath, redisAddr string) (store.ResultStore, error) {
+	switch kind {
+	case "memory":
+		return store.NewMemoryStore(), nil
+	case "bolt":
+		return store.NewBoltStore(boltPath)
+	case "redis":
+		return store.NewRedisStore(redisAddr), nil
+	default:
+		return nil, fmt.Errorf("unknown result store backend %q", kind)
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func resultTTLForVerdict(verdict string) time.Duration {
+	switch verdict {
+	case "valid":
+		return resultTTLValid
+	case "invalid":
+		return resultTTLInvalid
+	default:
+		return resultTTLUnknown
+	}
 }
 
-// Check MX records
-func checkMXRecords(domain string) (bool, error) {
-	mxRecords, err := net.LookupMX(domain)
+// lookupCachedResponse returns a cached EmailResponse for key if the
+// result store holds a fresh entry.
+func lookupCachedResponse(key string) (EmailResponse, bool) {
+	if resultStore == nil {
+		return EmailResponse{}, false
+	}
+	data, fresh, err := resultStore.Get(key)
 	if err != nil {
-		return false, err
+		log.Printf("result store lookup failed for %s: %v", key, err)
+		return EmailResponse{}, false
+	}
+	if !fresh {
+		return EmailResponse{}, false
+	}
+	var cached EmailResponse
+	if err := json.Unmarshal(data, &cached); err != nil {
+		log.Printf("result store decode failed for %s: %v", key, err)
+		return EmailResponse{}, false
+	}
+	return cached, true
+}
+
+// cacheResponse persists response in the result store under key, using
+// the TTL for its verdict.
+func cacheResponse(key string, response EmailResponse) {
+	if resultStore == nil {
+		return
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("result store encode failed for %s: %v", key, err)
+		return
+	}
+	if err := resultStore.Set(key, data, resultTTLForVerdict(response.Verdict)); err != nil {
+		log.Printf("result store write failed for %s: %v", key, err)
 	}
-	return len(mxRecords) > 0, nil
 }
 
 // mxCacheEntry holds the cached MX records with a timestamp.
@@ -53,6 +144,26 @@ var (
 // Cache TTL for MX records.
 const mxCacheTTL = 5 * time.Minute
 
+// MXResolver resolves MX records for a domain. It exists so tests can
+// inject a stub resolver instead of hitting real DNS.
+type MXResolver interface {
+	LookupMX(domain string) ([]*net.MX, error)
+}
+
+type dnsMXResolver struct{}
+
+func (dnsMXResolver) LookupMX(domain string) ([]*net.MX, error) {
+	return net.LookupMX(domain)
+}
+
+// mxResolver is the MXResolver used by getMXRecordsCached. Tests may
+// swap it for a stub.
+var mxResolver MXResolver = dnsMXResolver{}
+
+// smtpPort is the port verifyEmailSMTP dials on the MX host. Tests may
+// override it to point at an in-process fake server.
+var smtpPort = "25"
+
 // getMXRecordsCached returns MX records for the domain using cache if available.
 func getMXRecordsCached(domain string) ([]*net.MX, error) {
 	mxCacheMutex.RLock()
@@ -62,7 +173,7 @@ func getMXRecordsCached(domain string) ([]*net.MX, error) {
 		return entry.records, nil
 	}
 
-	records, err := net.LookupMX(domain)
+	records, err := mxResolver.LookupMX(domain)
 	if err != nil {
 		return nil, err
 	}
@@ -76,36 +187,63 @@ func getMXRecordsCached(domain string) ([]*net.MX, error) {
 	return records, nil
 }
 
-// Verify email existence via SMTP
-func verifyEmailSMTP(email string) (bool, error) {
+const randomLocalPartLength = 32
+
+const randomLocalPartAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// randomLocalPart returns a random local-part used to probe a domain for
+// catch-all behaviour without colliding with a real mailbox.
+func randomLocalPart() string {
+	b := make([]byte, randomLocalPartLength)
+	for i := range b {
+		b[i] = randomLocalPartAlphabet[rand.Intn(len(randomLocalPartAlphabet))]
+	}
+	return string(b)
+}
+
+// Verify email existence via SMTP. It looks up the MX host and either
+// delegates to a provider-specific verifier.Verifier (for providers
+// where port-25 RCPT probing is unreliable or blocked; the MX host is
+// not dialed in that case) or dials it for the generic RCPT flow, which
+// also probes the domain for catch-all
+// behaviour (accepting any recipient) on the same session.
+func verifyEmailSMTP(email string) (valid bool, catchAll bool, err error) {
 	parts := strings.Split(email, "@")
 	if len(parts) != 2 {
-		return false, fmt.Errorf("invalid email format")
+		return false, false, fmt.Errorf("invalid email format")
 	}
-	domain := parts[1]
-	mxValid, err := checkMXRecords(domain)
-	if err != nil || !mxValid {
-		return false, fmt.Errorf("no valid MX records found")
+	local, domain := parts[0], parts[1]
+
+	mxRecords, err := getMXRecordsCached(domain)
+	if err != nil || len(mxRecords) == 0 {
+		return false, false, fmt.Errorf("no valid MX records found")
 	}
+	server := mxRecords[0].Host
 
-	mxRecords, _ := net.LookupMX(domain)
-	if len(mxRecords) == 0 {
-		return false, fmt.Errorf("no MX records found")
+	if plugin := verifier.Lookup(server); plugin != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		res, err := plugin.Check(ctx, local, domain)
+		if err != nil {
+			return false, false, fmt.Errorf("%s verifier failed: %w", plugin.Name(), err)
+		}
+		return res.Valid, res.CatchAll, nil
 	}
 
 	// Connect to the SMTP server
-	server := mxRecords[0].Host
-	conn, err := net.Dial("tcp", server+":25")
+	conn, err := net.Dial("tcp", net.JoinHostPort(server, smtpPort))
 	if err != nil {
-		return false, fmt.Errorf("SMTP connection failed")
+		return false, false, fmt.Errorf("SMTP connection failed")
 	}
 	defer conn.Close()
 
 	client, err := smtp.NewClient(conn, server)
 	if err != nil {
-		return false, fmt.Errorf("SMTP client initialization failed")
+		return false, false, fmt.Errorf("SMTP client initialization failed")
 	}
-	defer client.Close()
+	// Quit (not Close) so the session ends with a proper QUIT instead of
+	// just dropping the TCP connection, the way a real MTA expects.
+	defer client.Quit()
 
 	// Send HELLO
 	client.Hello("localhost")
@@ -113,41 +251,195 @@ func verifyEmailSMTP(email string) (bool, error) {
 	// Check recipient address
 	err = client.Mail("check@yourdomain.com") // Use a valid sender domain
 	if err != nil {
-		return false, fmt.Errorf("MAIL FROM command failed")
+		return false, false, fmt.Errorf("MAIL FROM command failed")
+	}
+
+	// Probe for a catch-all domain before checking the real address: if a
+	// made-up recipient is accepted, the domain accepts everything and the
+	// real address's verdict can't be trusted.
+	if rcptErr := client.Rcpt(randomLocalPart() + "@" + domain); rcptErr == nil {
+		catchAll = true
 	}
 
 	err = client.Rcpt(email)
 	if err != nil {
-		return false, fmt.Errorf("RCPT TO command failed")
+		return false, catchAll, fmt.Errorf("RCPT TO command failed")
 	}
 
-	return true, nil
+	return true, catchAll, nil
 }
 
-func checkEmailHandler(c *gin.Context) {
-	var req EmailRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
-		return
+// Defaults for the bulk verification SMTPPool: stay at or below 1
+// request/second against any single MX host, and give a greylisted
+// recipient a few backed-off retries before giving up on it.
+const (
+	bulkRatePerSecondPerHost = 1.0
+	bulkGreylistRetries      = 3
+)
+
+// bulkGlobalConcurrencyLimit bounds total in-flight verifications
+// across a batch, regardless of how many distinct MX hosts it spans.
+// smtppool.Pool only caps concurrency *per host*, so a list spanning
+// thousands of domains would otherwise open one goroutine and one live
+// SMTP connection per host simultaneously.
+const bulkGlobalConcurrencyLimit = 10
+
+// runWithGlobalLimit calls fn once per item, with at most
+// bulkGlobalConcurrencyLimit calls running concurrently, and blocks
+// until every call has returned.
+func runWithGlobalLimit(items []string, fn func(item string)) {
+	sem := make(chan struct{}, bulkGlobalConcurrencyLimit)
+	var wg sync.WaitGroup
+	for _, item := range items {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(it string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(it)
+		}(item)
+	}
+	wg.Wait()
+}
+
+// verifyEmailViaPool checks email the same way verifyEmailSMTP does,
+// but dials through pool so the connection (and its rate limit) is
+// shared with every other address destined for the same MX host in the
+// batch.
+func verifyEmailViaPool(pool *smtppool.Pool, email string) (valid bool, catchAll bool, err error) {
+	parts := strings.Split(email, "@")
+	if len(parts) != 2 {
+		return false, false, fmt.Errorf("invalid email format")
+	}
+	local, domain := parts[0], parts[1]
+
+	mxRecords, err := getMXRecordsCached(domain)
+	if err != nil || len(mxRecords) == 0 {
+		return false, false, fmt.Errorf("no valid MX records found")
 	}
+	host := mxRecords[0].Host
+
+	if plugin := verifier.Lookup(host); plugin != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		res, err := plugin.Check(ctx, local, domain)
+		if err != nil {
+			return false, false, fmt.Errorf("%s verifier failed: %w", plugin.Name(), err)
+		}
+		return res.Valid, res.CatchAll, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Probe for catch-all and check the real address in the same
+	// transaction, same as the generic RCPT flow, before trusting a
+	// "valid" verdict for the real address.
+	catchAll, accepted, err := pool.CheckCatchAll(ctx, host, "check@yourdomain.com", randomLocalPart()+"@"+domain, email, bulkGreylistRetries)
+	if err != nil {
+		return false, catchAll, err
+	}
+	return accepted, catchAll, nil
+}
 
-	valid, err := verifyEmailSMTP(req.Email)
-	response := EmailResponse{
-		Email:   req.Email,
-		Valid:   valid,
-		Message: "Email exists",
+// classify applies syntax validation and the static disposable/free/role
+// classification, without making any network calls. The returned bool
+// is false if the address failed syntax validation, in which case the
+// caller should not attempt SMTP verification.
+func classify(email string) (EmailResponse, bool) {
+	response := EmailResponse{Email: email}
+
+	parts := strings.SplitN(email, "@", 2)
+	if len(parts) != 2 || parts[0] == "" || !strings.Contains(parts[1], ".") {
+		response.Message = "invalid email syntax"
+		response.Verdict = "invalid"
+		return response, false
 	}
+	response.SyntaxValid = true
+
+	local := strings.ToLower(parts[0])
+	domain := strings.ToLower(parts[1])
+	response.Disposable = isDisposableDomain(domain)
+	response.FreeProvider = isFreeProvider(domain)
+	response.RoleAccount = isRoleLocalPart(local)
+	return response, true
+}
 
+// finishVerification fills in the SMTP-derived fields of response given
+// the outcome of a verification attempt, whether from verifyEmailSMTP
+// or a smtppool.Pool-backed bulk check.
+func finishVerification(response EmailResponse, valid, catchAll bool, err error) EmailResponse {
+	response.CatchAll = catchAll
 	if err != nil {
 		response.Valid = false
 		response.Message = err.Error()
+		response.Verdict = verdictForSMTPError(err)
+		return response
+	}
+
+	if catchAll {
+		response.Valid = false
+		response.Message = "unknown: domain accepts all recipients (catch-all)"
+		response.Verdict = "unknown"
+		return response
+	}
+
+	if !valid {
+		response.Valid = false
+		response.Message = "Email does not exist"
+		response.Verdict = "invalid"
+		return response
+	}
+
+	response.Valid = valid
+	response.Message = "Email exists"
+	response.Verdict = "valid"
+	return response
+}
+
+// classifyAndVerify runs syntax validation, disposable/free/role
+// classification, and the SMTP verification pass for a single address.
+func classifyAndVerify(email string) EmailResponse {
+	response, ok := classify(email)
+	if !ok {
+		return response
+	}
+
+	valid, catchAll, err := verifyEmailSMTP(email)
+	return finishVerification(response, valid, catchAll, err)
+}
+
+// verdictForSMTPError classifies a verifyEmailSMTP error as "invalid"
+// when the mailbox was definitively rejected, or "unknown" when the
+// failure was inconclusive (e.g. no MX records, connection refused).
+func verdictForSMTPError(err error) string {
+	if strings.Contains(err.Error(), "RCPT TO command failed") {
+		return "invalid"
+	}
+	return "unknown"
+}
+
+func checkEmailHandler(c *gin.Context) {
+	var req EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	key := normalizeEmail(req.Email)
+	if cached, ok := lookupCachedResponse(key); ok {
+		c.JSON(http.StatusOK, cached)
+		return
 	}
 
+	response := classifyAndVerify(req.Email)
+	cacheResponse(key, response)
 	c.JSON(http.StatusOK, response)
 }
 
-// checkBulkEmailsStreamHandler handles bulk email verification using SSE,
-// concurrency limit, and sends real-time responses.
+// checkBulkEmailsStreamHandler handles bulk email verification using
+// SSE, a per-destination-MX rate-limited SMTPPool, and real-time
+// responses.
 func checkBulkEmailsStreamHandler(c *gin.Context) {
 	var req BulkEmailRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -160,40 +452,46 @@ func checkBulkEmailsStreamHandler(c *gin.Context) {
 	c.Writer.Header().Set("Cache-Control", "no-cache")
 	c.Writer.Header().Set("Connection", "keep-alive")
 
-	// Semaphore for concurrency limit (e.g., max 10 goroutines at once).
-	concurrencyLimit := 10
-	sem := make(chan struct{}, concurrencyLimit)
-	var wg sync.WaitGroup
 	var writeMutex sync.Mutex
 
+	// Batch-lookup the result store first and stream cached hits
+	// immediately, before spawning any goroutines for the misses.
+	misses := make([]string, 0, len(req.Emails))
 	for _, email := range req.Emails {
-		wg.Add(1)
-		sem <- struct{}{} // Acquire token.
-		go func(mail string) {
-			defer wg.Done()
-			defer func() { <-sem }() // Release token.
-
-			valid, err := verifyEmailSMTP(mail)
-			res := EmailResponse{
-				Email:   mail,
-				Valid:   valid,
-				Message: "Email exists",
-			}
-			if err != nil {
-				res.Valid = false
-				res.Message = err.Error()
-			}
-
-			// Send SSE event in a thread-safe manner.
+		key := normalizeEmail(email)
+		if cached, ok := lookupCachedResponse(key); ok {
 			writeMutex.Lock()
-			c.SSEvent("emailResult", res)
+			c.SSEvent("emailResult", cached)
 			c.Writer.Flush()
 			writeMutex.Unlock()
-		}(email)
+			continue
+		}
+		misses = append(misses, email)
 	}
 
-	// Wait for all goroutines to finish.
-	wg.Wait()
+	// SMTPPool keeps one warm, rate-limited connection per destination
+	// MX host for the batch, so a large list dials each provider once
+	// instead of once per address (and never exceeds its rate limit).
+	// runWithGlobalLimit bounds total concurrent work on top of that,
+	// since the pool's per-host serialization alone doesn't cap how
+	// many distinct hosts run at once.
+	pool := smtppool.New(smtppool.WithRatePerSecond(bulkRatePerSecondPerHost))
+	defer pool.Close()
+
+	runWithGlobalLimit(misses, func(mail string) {
+		response, ok := classify(mail)
+		if ok {
+			valid, catchAll, err := verifyEmailViaPool(pool, mail)
+			response = finishVerification(response, valid, catchAll, err)
+		}
+		cacheResponse(normalizeEmail(mail), response)
+
+		// Send SSE event in a thread-safe manner.
+		writeMutex.Lock()
+		c.SSEvent("emailResult", response)
+		c.Writer.Flush()
+		writeMutex.Unlock()
+	})
 
 	// Send an "end" event to signal completion.
 	writeMutex.Lock()
@@ -215,6 +513,35 @@ func health(c *gin.Context) {
 }
 
 func main() {
+	listsDirFlag := flag.String("lists-dir", "", "directory holding disposable_domains.txt/free_providers.txt overrides (falls back to built-in lists)")
+	disableVerifiersFlag := flag.String("disable-verifiers", "", "comma-separated list of provider verifier plugins to disable (e.g. yahoo,hotmail)")
+	resultStoreKindFlag := flag.String("result-store", "memory", "result store backend: memory, bolt, or redis")
+	resultStorePathFlag := flag.String("result-store-path", "results.db", "file path for the bolt result store backend")
+	redisAddrFlag := flag.String("redis-addr", "localhost:6379", "address for the redis result store backend")
+	jobStoreKindFlag := flag.String("job-store", "memory", "job store backend: memory or bolt")
+	jobStorePathFlag := flag.String("job-store-path", "jobs.db", "file path for the bolt job store backend")
+	flag.Parse()
+
+	initLists(*listsDirFlag)
+	if *disableVerifiersFlag != "" {
+		verifier.SetDisabled(strings.Split(*disableVerifiersFlag, ","))
+	}
+
+	rs, err := newResultStore(*resultStoreKindFlag, *resultStorePathFlag, *redisAddrFlag)
+	if err != nil {
+		log.Fatalf("failed to initialize result store: %v", err)
+	}
+	resultStore = rs
+	defer resultStore.Close()
+
+	js, err := newJobStore(*jobStoreKindFlag, *jobStorePathFlag)
+	if err != nil {
+		log.Fatalf("failed to initialize job store: %v", err)
+	}
+	jobStore = js
+	defer jobStore.Close()
+	resumeJobs()
+
 	r := gin.Default()
 
 	// Enable CORS for all origins (unsafe for production)
@@ -225,6 +552,15 @@ func main() {
 	// Bulk email verification endpoint using SSE.
 	r.POST("/check-emails", checkBulkEmailsStreamHandler)
 
+	// Async job API: queue a batch, then poll/stream/download its
+	// results independently of the original request's lifetime.
+	r.POST("/jobs", createJobHandler)
+	r.GET("/jobs/:id", getJobHandler)
+	r.GET("/jobs/:id/stream", jobStreamHandler)
+	r.GET("/jobs/:id/results.csv", jobResultsHandler("csv"))
+	r.GET("/jobs/:id/results.json", jobResultsHandler("json"))
+	r.GET("/jobs/:id/results.ndjson", jobResultsHandler("ndjson"))
+
 	port := ":8080"
 	log.Printf("Starting server on %s...", port)
 	r.Run(port)
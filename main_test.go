@@ -0,0 +1,102 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/is-it-fake/core/testutil/smtpserver"
+)
+
+type stubMXResolver struct {
+	host string
+}
+
+func (s stubMXResolver) LookupMX(domain string) ([]*net.MX, error) {
+	return []*net.MX{{Host: s.host, Pref: 10}}, nil
+}
+
+// withFakeServer starts srv, points verifyEmailSMTP at it for the
+// duration of the test, and restores the previous resolver/port on
+// cleanup.
+func withFakeServer(t *testing.T, srv *smtpserver.Server) {
+	t.Helper()
+	if err := srv.Start(); err != nil {
+		t.Fatalf("starting fake smtp server: %v", err)
+	}
+
+	host, port, err := net.SplitHostPort(srv.Addr())
+	if err != nil {
+		srv.Close()
+		t.Fatalf("splitting fake server address: %v", err)
+	}
+
+	origResolver, origPort := mxResolver, smtpPort
+	mxResolver = stubMXResolver{host: host}
+	smtpPort = port
+
+	t.Cleanup(func() {
+		mxResolver = origResolver
+		smtpPort = origPort
+		srv.Close()
+	})
+}
+
+func TestVerifyEmailSMTP_ValidRecipientNotCatchAll(t *testing.T) {
+	srv := smtpserver.New(smtpserver.Config{
+		Default: smtpserver.Reject550,
+		Behaviors: map[string]smtpserver.Behavior{
+			"person@example.com": smtpserver.Accept,
+		},
+	})
+	withFakeServer(t, srv)
+
+	valid, catchAll, err := verifyEmailSMTP("person@example.com")
+	if err != nil {
+		t.Fatalf("verifyEmailSMTP returned error: %v", err)
+	}
+	if !valid {
+		t.Errorf("expected valid=true for an accepted recipient")
+	}
+	if catchAll {
+		t.Errorf("expected catchAll=false when only the real recipient is accepted")
+	}
+
+	select {
+	case env := <-srv.Envelopes:
+		if len(env.To) == 0 || env.To[len(env.To)-1] != "person@example.com" {
+			t.Errorf("expected RCPT TO for person@example.com as the final recipient, got %+v", env)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for captured envelope")
+	}
+}
+
+func TestVerifyEmailSMTP_CatchAllDomain(t *testing.T) {
+	srv := smtpserver.New(smtpserver.Config{Default: smtpserver.Accept})
+	withFakeServer(t, srv)
+
+	valid, catchAll, err := verifyEmailSMTP("person@example.com")
+	if err != nil {
+		t.Fatalf("verifyEmailSMTP returned error: %v", err)
+	}
+	if !valid {
+		t.Errorf("expected valid=true since the server accepts the real recipient too")
+	}
+	if !catchAll {
+		t.Errorf("expected catchAll=true when the domain accepts any recipient")
+	}
+}
+
+func TestVerifyEmailSMTP_RejectedRecipient(t *testing.T) {
+	srv := smtpserver.New(smtpserver.Config{Default: smtpserver.Reject550})
+	withFakeServer(t, srv)
+
+	valid, _, err := verifyEmailSMTP("person@example.com")
+	if err == nil {
+		t.Fatal("expected an error for a rejected recipient")
+	}
+	if valid {
+		t.Errorf("expected valid=false for a rejected recipient")
+	}
+}
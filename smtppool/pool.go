@@ -0,0 +1,272 @@
+// Package smtppool manages rate-limited, reusable SMTP sessions to
+// destination MX hosts for bulk verification batches, so a large
+// address list dials at most one connection per host instead of one
+// per address, and never exceeds a configured request rate against any
+// single provider.
+package smtppool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"sync"
+	"time"
+)
+
+// Dialer opens and HELOs an SMTP session against host:port. Overridable
+// in tests.
+type Dialer func(ctx context.Context, host, port string) (*smtp.Client, error)
+
+func defaultDialer(ctx context.Context, host, port string) (*smtp.Client, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := client.Hello("localhost"); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// Greylisted reports whether code is a transient greylisting response
+// worth retrying rather than a hard rejection.
+func Greylisted(code int) bool {
+	switch code {
+	case 421, 450, 451:
+		return true
+	}
+	return false
+}
+
+// Option configures a Pool.
+type Option func(*Pool)
+
+// WithDialer overrides how sessions are opened, e.g. to point at a fake
+// server in tests.
+func WithDialer(d Dialer) Option { return func(p *Pool) { p.dial = d } }
+
+// WithPort overrides the port dialed on each MX host (default "25").
+func WithPort(port string) Option { return func(p *Pool) { p.port = port } }
+
+// WithRatePerSecond caps requests per second to any single MX host
+// (default 1).
+func WithRatePerSecond(r float64) Option { return func(p *Pool) { p.ratePerSec = r } }
+
+// Pool keeps at most one warm, rate-limited SMTP session per
+// destination MX host for the lifetime of a bulk batch.
+type Pool struct {
+	mu       sync.Mutex
+	sessions map[string]*hostSession
+
+	dial       Dialer
+	port       string
+	ratePerSec float64
+}
+
+// New creates an empty Pool.
+func New(opts ...Option) *Pool {
+	p := &Pool{
+		sessions:   make(map[string]*hostSession),
+		dial:       defaultDialer,
+		port:       "25",
+		ratePerSec: 1,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *Pool) sessionFor(host string) *hostSession {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, ok := p.sessions[host]
+	if !ok {
+		s = newHostSession(host, p.port, p.dial, p.ratePerSec)
+		p.sessions[host] = s
+	}
+	return s
+}
+
+// Check verifies recipient via a warm session to host, sending from as
+// the envelope sender. On a transient greylist response (421/450/451)
+// it backs off exponentially and retries, up to maxRetries times,
+// keeping the retried recipient within the same batch instead of
+// giving up immediately.
+func (p *Pool) Check(ctx context.Context, host, from, recipient string, maxRetries int) (accepted bool, err error) {
+	session := p.sessionFor(host)
+
+	backoff := 2 * time.Second
+	for attempt := 0; ; attempt++ {
+		accepted, code, checkErr := session.check(ctx, from, recipient)
+		if checkErr == nil {
+			return accepted, nil
+		}
+		if !Greylisted(code) || attempt >= maxRetries {
+			return false, checkErr
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// CheckCatchAll probes probeRecipient and then checks recipient in a
+// single transaction (one MAIL FROM, both RCPTs, then RSET), the same
+// way the non-pooled generic RCPT flow does. Sharing the transaction
+// avoids a second dial/MAIL-FROM round trip for the probe alone, and
+// means a greylist retry only backs off once for both recipients
+// instead of once per recipient.
+func (p *Pool) CheckCatchAll(ctx context.Context, host, from, probeRecipient, recipient string, maxRetries int) (probeAccepted, accepted bool, err error) {
+	session := p.sessionFor(host)
+
+	backoff := 2 * time.Second
+	for attempt := 0; ; attempt++ {
+		probeAccepted, accepted, code, checkErr := session.checkCatchAll(ctx, from, probeRecipient, recipient)
+		if checkErr == nil {
+			return probeAccepted, accepted, nil
+		}
+		if !Greylisted(code) || attempt >= maxRetries {
+			return false, false, checkErr
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return false, false, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// Close tears down every warm connection held by the pool.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, s := range p.sessions {
+		s.close()
+	}
+}
+
+// hostSession is a single warm, mutex-serialized SMTP connection to one
+// MX host, rate-limited to ratePerSec requests/second. Serializing on
+// the mutex also caps concurrent connections to this host at one,
+// which is the per-destination-MX limit the pool exists to enforce.
+type hostSession struct {
+	mu      sync.Mutex
+	client  *smtp.Client
+	host    string
+	port    string
+	dial    Dialer
+	limiter *rateLimiter
+}
+
+func newHostSession(host, port string, dial Dialer, ratePerSec float64) *hostSession {
+	return &hostSession{host: host, port: port, dial: dial, limiter: newRateLimiter(ratePerSec)}
+}
+
+func (s *hostSession) ensureClient(ctx context.Context) (*smtp.Client, error) {
+	if s.client != nil {
+		return s.client, nil
+	}
+	client, err := s.dial(ctx, s.host, s.port)
+	if err != nil {
+		return nil, err
+	}
+	s.client = client
+	return client, nil
+}
+
+// check sends MAIL FROM/RCPT TO for one recipient over the warm
+// connection, then RSETs so the session is clean for the next
+// recipient.
+func (s *hostSession) check(ctx context.Context, from, recipient string) (accepted bool, code int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limiter.wait()
+
+	client, err := s.ensureClient(ctx)
+	if err != nil {
+		return false, 0, fmt.Errorf("dialing %s failed: %w", s.host, err)
+	}
+
+	if err := client.Mail(from); err != nil {
+		// The connection is likely dead; drop it so the next check
+		// redials instead of repeatedly failing against a stale client.
+		s.client = nil
+		return false, smtpErrorCode(err), fmt.Errorf("MAIL FROM command failed: %w", err)
+	}
+
+	rcptErr := client.Rcpt(recipient)
+	_ = client.Reset()
+
+	if rcptErr == nil {
+		return true, 250, nil
+	}
+	return false, smtpErrorCode(rcptErr), fmt.Errorf("RCPT TO command failed: %w", rcptErr)
+}
+
+// checkCatchAll is check, but probes probeRecipient before recipient in
+// the same MAIL FROM transaction instead of opening a second one.
+// probeRecipient's acceptance is only ever informational: only
+// recipient's code/error drives greylist retry.
+func (s *hostSession) checkCatchAll(ctx context.Context, from, probeRecipient, recipient string) (probeAccepted, accepted bool, code int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limiter.wait()
+
+	client, err := s.ensureClient(ctx)
+	if err != nil {
+		return false, false, 0, fmt.Errorf("dialing %s failed: %w", s.host, err)
+	}
+
+	if err := client.Mail(from); err != nil {
+		s.client = nil
+		return false, false, smtpErrorCode(err), fmt.Errorf("MAIL FROM command failed: %w", err)
+	}
+
+	probeAccepted = client.Rcpt(probeRecipient) == nil
+
+	rcptErr := client.Rcpt(recipient)
+	_ = client.Reset()
+
+	if rcptErr == nil {
+		return probeAccepted, true, 250, nil
+	}
+	return probeAccepted, false, smtpErrorCode(rcptErr), fmt.Errorf("RCPT TO command failed: %w", rcptErr)
+}
+
+func (s *hostSession) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client != nil {
+		s.client.Close()
+		s.client = nil
+	}
+}
+
+func smtpErrorCode(err error) int {
+	var textErr *textproto.Error
+	if errors.As(err, &textErr) {
+		return textErr.Code
+	}
+	return 0
+}
@@ -0,0 +1,84 @@
+package smtppool_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/is-it-fake/core/smtppool"
+	"github.com/is-it-fake/core/testutil/smtpserver"
+)
+
+// startServer starts srv and returns the host/port to dial it on.
+func startServer(t *testing.T, cfg smtpserver.Config) (string, string) {
+	t.Helper()
+	srv := smtpserver.New(cfg)
+	if err := srv.Start(); err != nil {
+		t.Fatalf("starting fake smtp server: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	host, port, err := net.SplitHostPort(srv.Addr())
+	if err != nil {
+		t.Fatalf("splitting fake server address: %v", err)
+	}
+	return host, port
+}
+
+func TestPool_Check_GreylistedRecipientRetriedThenAccepted(t *testing.T) {
+	host, port := startServer(t, smtpserver.Config{
+		Behaviors: map[string]smtpserver.Behavior{
+			"person@example.com": smtpserver.Greylist,
+		},
+		Default: smtpserver.Accept,
+	})
+
+	pool := smtppool.New(smtppool.WithPort(port), smtppool.WithRatePerSecond(1000))
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accepted, err := pool.Check(ctx, host, "check@yourdomain.com", "person@example.com", 3)
+	if err != nil {
+		t.Fatalf("expected the greylisted recipient to be accepted after a retry, got error: %v", err)
+	}
+	if !accepted {
+		t.Errorf("expected accepted=true after retrying a greylisted recipient")
+	}
+}
+
+func TestPool_Check_DroppedConnectionSurfacesErrorWithoutHanging(t *testing.T) {
+	host, port := startServer(t, smtpserver.Config{
+		Behaviors: map[string]smtpserver.Behavior{
+			"person@example.com": smtpserver.DropMidRCPT,
+		},
+		Default: smtpserver.Accept,
+	})
+
+	pool := smtppool.New(smtppool.WithPort(port), smtppool.WithRatePerSecond(1000))
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	type result struct {
+		accepted bool
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		accepted, err := pool.Check(ctx, host, "check@yourdomain.com", "person@example.com", 3)
+		done <- result{accepted, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err == nil {
+			t.Fatalf("expected an error for a connection dropped mid-RCPT, got accepted=%v", r.accepted)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Pool.Check did not return after the connection was dropped")
+	}
+}
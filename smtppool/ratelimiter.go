@@ -0,0 +1,44 @@
+package smtppool
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter is a simple single-token-bucket limiter: it allows at
+// most ratePerSec operations per second, blocking wait callers as
+// needed to stay under that rate.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(ratePerSec float64) *rateLimiter {
+	return &rateLimiter{rate: ratePerSec, tokens: 1, last: time.Now()}
+}
+
+// wait blocks, if necessary, until a token is available, then consumes
+// it. Must be called with the caller already holding any lock that
+// serializes use of the underlying connection.
+func (r *rateLimiter) wait() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.tokens += now.Sub(r.last).Seconds() * r.rate
+	if r.tokens > 1 {
+		r.tokens = 1
+	}
+	r.last = now
+
+	if r.tokens < 1 {
+		wait := time.Duration((1 - r.tokens) / r.rate * float64(time.Second))
+		time.Sleep(wait)
+		r.tokens = 0
+		r.last = time.Now()
+		return
+	}
+	r.tokens--
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"encoding/binary"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var resultsBucket = []byte("results")
+
+// BoltStore is a ResultStore backed by an embedded BoltDB file, so
+// cached verdicts survive process restarts.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore opens (creating if necessary) a BoltDB file at path.
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(resultsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// Get reads the entry for email. Entries are stored as an 8-byte
+// big-endian unix expiry timestamp followed by the cached bytes.
+func (b *BoltStore) Get(email string) ([]byte, bool, error) {
+	var data []byte
+	var expiresAt int64
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(resultsBucket).Get([]byte(email))
+		if len(v) < 8 {
+			return nil
+		}
+		expiresAt = int64(binary.BigEndian.Uint64(v[:8]))
+		data = append([]byte(nil), v[8:]...)
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if data == nil || time.Now().Unix() > expiresAt {
+		return nil, false, nil
+	}
+	return data, true, nil
+}
+
+func (b *BoltStore) Set(email string, data []byte, ttl time.Duration) error {
+	buf := make([]byte, 8+len(data))
+	binary.BigEndian.PutUint64(buf[:8], uint64(time.Now().Add(ttl).Unix()))
+	copy(buf[8:], data)
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(resultsBucket).Put([]byte(email), buf)
+	})
+}
+
+func (b *BoltStore) Close() error {
+	return b.db.Close()
+}
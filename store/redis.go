@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore is a ResultStore backed by a Redis instance, shared
+// across process instances.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore creates a RedisStore against addr (host:port).
+func NewRedisStore(addr string) *RedisStore {
+	return &RedisStore{
+		client: redis.NewClient(&redis.Options{Addr: addr}),
+		prefix: "emailverify:",
+	}
+}
+
+func (r *RedisStore) Get(email string) ([]byte, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	data, err := r.client.Get(ctx, r.prefix+email).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (r *RedisStore) Set(email string, data []byte, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return r.client.Set(ctx, r.prefix+email, data, ttl).Err()
+}
+
+func (r *RedisStore) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,58 @@
+// Package store provides a pluggable cache for verification results,
+// keyed by normalized email address with a per-entry TTL chosen by the
+// caller (so, e.g., a "valid" verdict can be cached longer than an
+// "unknown" one).
+package store
+
+import (
+	"sync"
+	"time"
+)
+
+// ResultStore caches verification results as raw (typically
+// JSON-encoded) bytes, keyed by normalized email address.
+type ResultStore interface {
+	// Get returns the cached bytes for email and whether the entry is
+	// still fresh. A false fresh with a nil error means no usable entry
+	// was found.
+	Get(email string) (data []byte, fresh bool, err error)
+	// Set stores data for email, expiring after ttl.
+	Set(email string, data []byte, ttl time.Duration) error
+	Close() error
+}
+
+type memoryEntry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+// MemoryStore is an in-process ResultStore backed by a map. It does not
+// survive process restarts.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	entries map[string]memoryEntry
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry)}
+}
+
+func (m *MemoryStore) Get(email string) ([]byte, bool, error) {
+	m.mu.RLock()
+	entry, ok := m.entries[email]
+	m.mu.RUnlock()
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false, nil
+	}
+	return entry.data, true, nil
+}
+
+func (m *MemoryStore) Set(email string, data []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[email] = memoryEntry{data: data, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (m *MemoryStore) Close() error { return nil }
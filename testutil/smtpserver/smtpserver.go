@@ -0,0 +1,206 @@
+// Package smtpserver provides an in-process, capture-oriented SMTP
+// server for tests — MailHog/Mailpit-style, but driven by configurable
+// per-recipient behaviors instead of a UI, and with captured envelopes
+// exposed over a channel for assertions.
+package smtpserver
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Behavior controls how the server responds to RCPT TO for a given
+// recipient address.
+type Behavior int
+
+const (
+	// Accept always returns 250 for RCPT TO.
+	Accept Behavior = iota
+	// Reject550 always returns 550 (mailbox unavailable).
+	Reject550
+	// Greylist returns 450 the first time the server sees RCPT TO for a
+	// recipient and 250 on every later attempt, in any transaction or
+	// connection.
+	Greylist
+	// Tarpit delays the RCPT TO response by Config.TarpitDelay before
+	// otherwise behaving like Accept.
+	Tarpit
+	// DropMidRCPT closes the connection without responding to RCPT TO.
+	DropMidRCPT
+)
+
+// Envelope is a captured MAIL FROM / RCPT TO sequence for one SMTP
+// transaction (ended by RSET or QUIT).
+type Envelope struct {
+	From string
+	To   []string
+}
+
+// Config configures a Server's per-recipient behaviors.
+type Config struct {
+	// Behaviors maps a recipient address (case-insensitive) to the
+	// behavior the server exhibits for it. Addresses not present use
+	// Default.
+	Behaviors map[string]Behavior
+	// Default is used for recipients not present in Behaviors.
+	Default Behavior
+	// TarpitDelay is how long a Tarpit recipient's RCPT TO stalls.
+	// Defaults to 2 seconds.
+	TarpitDelay time.Duration
+}
+
+// Server is an in-process SMTP listener that captures envelopes and
+// exhibits configurable per-recipient behavior.
+type Server struct {
+	cfg Config
+
+	listener  net.Listener
+	Envelopes chan Envelope
+
+	mu         sync.Mutex
+	greylisted map[string]bool
+	wg         sync.WaitGroup
+}
+
+// New creates a Server with the given configuration. Call Start to
+// begin listening.
+func New(cfg Config) *Server {
+	if cfg.TarpitDelay == 0 {
+		cfg.TarpitDelay = 2 * time.Second
+	}
+	return &Server{
+		cfg:        cfg,
+		Envelopes:  make(chan Envelope, 16),
+		greylisted: make(map[string]bool),
+	}
+}
+
+// Start begins listening on an OS-assigned loopback port.
+func (s *Server) Start() error {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	s.listener = l
+
+	s.wg.Add(1)
+	go s.acceptLoop()
+	return nil
+}
+
+// Addr returns the "host:port" the server is listening on.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops accepting new connections and waits for in-flight ones
+// to finish, then closes Envelopes.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	close(s.Envelopes)
+	return err
+}
+
+func (s *Server) acceptLoop() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handleConn(conn)
+		}()
+	}
+}
+
+func (s *Server) behaviorFor(recipient string) Behavior {
+	if b, ok := s.cfg.Behaviors[strings.ToLower(recipient)]; ok {
+		return b
+	}
+	return s.cfg.Default
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	var env Envelope
+	reader := bufio.NewReader(conn)
+
+	fmt.Fprintf(conn, "220 smtpserver ESMTP ready\r\n")
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		upper := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(upper, "HELO"), strings.HasPrefix(upper, "EHLO"):
+			fmt.Fprintf(conn, "250 smtpserver\r\n")
+
+		case strings.HasPrefix(upper, "MAIL FROM:"):
+			env = Envelope{From: strings.TrimSpace(line[len("MAIL FROM:"):])}
+			fmt.Fprintf(conn, "250 OK\r\n")
+
+		case strings.HasPrefix(upper, "RCPT TO:"):
+			recipient := strings.Trim(strings.TrimSpace(line[len("RCPT TO:"):]), "<>")
+			env.To = append(env.To, recipient)
+
+			switch s.behaviorFor(recipient) {
+			case Reject550:
+				fmt.Fprintf(conn, "550 mailbox unavailable\r\n")
+			case Greylist:
+				s.mu.Lock()
+				seen := s.greylisted[recipient]
+				s.greylisted[recipient] = true
+				s.mu.Unlock()
+				if !seen {
+					fmt.Fprintf(conn, "450 greylisted, try again later\r\n")
+				} else {
+					fmt.Fprintf(conn, "250 OK\r\n")
+				}
+			case Tarpit:
+				time.Sleep(s.cfg.TarpitDelay)
+				fmt.Fprintf(conn, "250 OK\r\n")
+			case DropMidRCPT:
+				s.captureEnvelope(env)
+				return
+			default: // Accept
+				fmt.Fprintf(conn, "250 OK\r\n")
+			}
+
+		case strings.HasPrefix(upper, "RSET"):
+			s.captureEnvelope(env)
+			env = Envelope{}
+			fmt.Fprintf(conn, "250 OK\r\n")
+
+		case strings.HasPrefix(upper, "QUIT"):
+			s.captureEnvelope(env)
+			fmt.Fprintf(conn, "221 bye\r\n")
+			return
+
+		default:
+			fmt.Fprintf(conn, "500 unrecognized command\r\n")
+		}
+	}
+}
+
+func (s *Server) captureEnvelope(env Envelope) {
+	if env.From == "" && len(env.To) == 0 {
+		return
+	}
+	select {
+	case s.Envelopes <- env:
+	default:
+	}
+}
@@ -0,0 +1,77 @@
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	Register(&hotmailVerifier{client: &http.Client{Timeout: 10 * time.Second}})
+}
+
+// hotmailVerifier checks mailbox existence via Microsoft's
+// GetCredentialType sign-in endpoint, which reports whether an account
+// exists for a given address without requiring a port-25 RCPT probe.
+type hotmailVerifier struct {
+	client *http.Client
+
+	mu       sync.Mutex
+	lastCall time.Time
+}
+
+const hotmailMinInterval = 2 * time.Second
+
+func (h *hotmailVerifier) Name() string { return "hotmail" }
+
+func (h *hotmailVerifier) Supports(mxHost string) bool {
+	mxHost = strings.ToLower(mxHost)
+	return strings.Contains(mxHost, "outlook.com") || strings.Contains(mxHost, "hotmail.com")
+}
+
+func (h *hotmailVerifier) Check(ctx context.Context, local, domain string) (Result, error) {
+	h.throttle()
+
+	payload, err := json.Marshal(map[string]string{"Username": local + "@" + domain})
+	if err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://login.live.com/GetCredentialType.srf", bytes.NewReader(payload))
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("hotmail availability request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IfExistsResult int `json:"IfExistsResult"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return Result{}, fmt.Errorf("hotmail availability response decode failed: %w", err)
+	}
+
+	// IfExistsResult == 0 means the account exists.
+	return Result{Valid: body.IfExistsResult == 0, Message: "hotmail: sign-in endpoint probe"}, nil
+}
+
+// throttle enforces a minimum spacing between requests so repeated
+// lookups don't trip Microsoft's rate limiting.
+func (h *hotmailVerifier) throttle() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if wait := hotmailMinInterval - time.Since(h.lastCall); wait > 0 {
+		time.Sleep(wait)
+	}
+	h.lastCall = time.Now()
+}
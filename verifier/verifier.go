@@ -0,0 +1,69 @@
+// Package verifier provides a pluggable dispatch point for mailbox
+// providers whose MX hosts don't respond reliably to the generic
+// RCPT-probing flow (Yahoo, Hotmail/Outlook, Gmail, iCloud, ...).
+package verifier
+
+import (
+	"context"
+	"sync"
+)
+
+// Result is the verdict returned by a provider-specific Verifier.
+type Result struct {
+	Valid    bool
+	CatchAll bool
+	Message  string
+}
+
+// Verifier checks mailbox existence for a provider using that
+// provider's own signal instead of a port-25 RCPT probe.
+type Verifier interface {
+	// Name identifies the plugin for logging and the -disable-verifiers flag.
+	Name() string
+	// Supports reports whether this plugin handles the given MX host.
+	Supports(mxHost string) bool
+	Check(ctx context.Context, local, domain string) (Result, error)
+}
+
+var (
+	mu       sync.RWMutex
+	plugins  []Verifier
+	disabled = map[string]struct{}{}
+)
+
+// Register adds a plugin to the registry. Plugins register themselves
+// from an init() in their own file.
+func Register(v Verifier) {
+	mu.Lock()
+	defer mu.Unlock()
+	plugins = append(plugins, v)
+}
+
+// SetDisabled replaces the set of plugin names excluded from Lookup,
+// e.g. from the -disable-verifiers flag.
+func SetDisabled(names []string) {
+	set := make(map[string]struct{}, len(names))
+	for _, n := range names {
+		set[n] = struct{}{}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	disabled = set
+}
+
+// Lookup returns the first enabled plugin that supports mxHost, or nil
+// if none match and the caller should fall back to the generic flow.
+func Lookup(mxHost string) Verifier {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, p := range plugins {
+		if _, off := disabled[p.Name()]; off {
+			continue
+		}
+		if p.Supports(mxHost) {
+			return p
+		}
+	}
+	return nil
+}
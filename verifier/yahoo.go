@@ -0,0 +1,79 @@
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	Register(&yahooVerifier{client: &http.Client{Timeout: 10 * time.Second}})
+}
+
+// yahooVerifier checks mailbox existence via Yahoo's signup "is this
+// userId taken" endpoint, since Yahoo's MX hosts routinely block or
+// silently swallow port-25 RCPT probes.
+type yahooVerifier struct {
+	client *http.Client
+
+	mu       sync.Mutex
+	lastCall time.Time
+}
+
+const yahooMinInterval = 2 * time.Second
+
+func (y *yahooVerifier) Name() string { return "yahoo" }
+
+func (y *yahooVerifier) Supports(mxHost string) bool {
+	mxHost = strings.ToLower(mxHost)
+	return strings.Contains(mxHost, "yahoodns.net") || strings.HasSuffix(mxHost, "yahoo.com")
+}
+
+func (y *yahooVerifier) Check(ctx context.Context, local, domain string) (Result, error) {
+	y.throttle()
+
+	endpoint := fmt.Sprintf("https://login.yahoo.com/account/module/create?validateField=userId&userId=%s", local)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Result{}, err
+	}
+
+	resp, err := y.client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("yahoo availability request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Errors []struct {
+			Name string `json:"name"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return Result{}, fmt.Errorf("yahoo availability response decode failed: %w", err)
+	}
+
+	// Yahoo reports IDENTIFIER_EXISTS when the userId is already taken,
+	// i.e. the mailbox exists.
+	for _, e := range body.Errors {
+		if e.Name == "IDENTIFIER_EXISTS" {
+			return Result{Valid: true, Message: "yahoo: mailbox exists"}, nil
+		}
+	}
+	return Result{Valid: false, Message: "yahoo: mailbox not found"}, nil
+}
+
+// throttle enforces a minimum spacing between requests so repeated
+// lookups don't trip Yahoo's rate limiting.
+func (y *yahooVerifier) throttle() {
+	y.mu.Lock()
+	defer y.mu.Unlock()
+	if wait := yahooMinInterval - time.Since(y.lastCall); wait > 0 {
+		time.Sleep(wait)
+	}
+	y.lastCall = time.Now()
+}